@@ -8,6 +8,8 @@ package gmap
 
 import (
 	"encoding/json"
+	"time"
+	"unsafe"
 
 	"github.com/gogf/gf/internal/empty"
 
@@ -18,6 +20,14 @@ import (
 type IntStrMap struct {
 	mu   *rwmutex.RWMutex
 	data map[int]string
+	// evict holds the optional TTL/eviction state of the map. It stays nil
+	// until the map is created with NewIntStrMapWithEviction or a *WithTTL
+	// method is called, so maps that never use TTL pay no extra cost.
+	evict *intStrMapEviction
+	// watch holds the optional event dispatcher of the map. It stays nil
+	// until Watch or BatchWatch is first called, so maps nobody watches
+	// pay no extra cost.
+	watch *intStrMapDispatcher
 }
 
 // NewIntStrMap returns an empty IntStrMap object.
@@ -98,54 +108,119 @@ func (m *IntStrMap) MapCopy() map[int]string {
 // FilterEmpty deletes all key-value pair of which the value is empty.
 func (m *IntStrMap) FilterEmpty() {
 	m.mu.Lock()
+	var events []Event
 	for k, v := range m.data {
 		if empty.IsEmpty(v) {
 			delete(m.data, k)
+			events = append(events, Event{Type: EventTypeRemove, Key: k, OldValue: v})
 		}
 	}
 	m.mu.Unlock()
+	m.notify(events...)
 }
 
 // Set sets key-value to the hash map.
+//
+// If the map was created with NewIntStrMapWithEviction, inserting a new key
+// while already at MaxSize evicts one entry first, per the configured
+// EvictionPolicy; an existing key's LRU/LFU bookkeeping is updated either way.
 func (m *IntStrMap) Set(key int, val string) {
 	m.mu.Lock()
+	old, existed := m.data[key]
+	var evictedKey int
+	var evictedVal string
+	var evicted bool
+	var onEvict func(key int, val string, reason EvictReason)
+	if m.evict != nil {
+		if !existed {
+			evictedKey, evictedVal, evicted = m.evictForCapacity()
+		}
+		switch m.evict.policy {
+		case EvictionPolicyLRU:
+			m.evict.touchLRU(key)
+		case EvictionPolicyLFU:
+			m.evict.touchLFU(key)
+		}
+		onEvict = m.evict.onEvict
+	}
 	m.data[key] = val
 	m.mu.Unlock()
+	if evicted {
+		m.notify(
+			Event{Type: EventTypeRemove, Key: evictedKey, OldValue: evictedVal},
+			Event{Type: EventTypeSet, Key: key, OldValue: old, NewValue: val},
+		)
+	} else {
+		m.notify(Event{Type: EventTypeSet, Key: key, OldValue: old, NewValue: val})
+	}
+	if evicted && onEvict != nil {
+		onEvict(evictedKey, evictedVal, EvictReasonCapacity)
+	}
 }
 
 // Sets batch sets key-values to the hash map.
 func (m *IntStrMap) Sets(data map[int]string) {
 	m.mu.Lock()
+	events := make([]Event, 0, len(data))
 	for k, v := range data {
+		events = append(events, Event{Type: EventTypeSet, Key: k, OldValue: m.data[k], NewValue: v})
 		m.data[k] = v
 	}
 	m.mu.Unlock()
+	m.notify(events...)
 }
 
 // Search searches the map with given <key>.
 // Second return parameter <found> is true if key was found, otherwise false.
+//
+// If the map was created with eviction configured, a key whose TTL has
+// already elapsed is swept on this access and reported as not found, even if
+// the background sweeper hasn't reached it yet; otherwise a found key has its
+// LRU/LFU recency/frequency bookkeeping updated.
 func (m *IntStrMap) Search(key int) (value string, found bool) {
 	m.mu.RLock()
 	value, found = m.data[key]
+	hasEvict := m.evict != nil
 	m.mu.RUnlock()
+	if found && hasEvict {
+		if m.touchAccess(key) {
+			return "", false
+		}
+	}
 	return
 }
 
 // Get returns the value by given <key>.
+//
+// If the map was created with eviction configured, a key whose TTL has
+// already elapsed is swept on this access and reported as absent, even if the
+// background sweeper hasn't reached it yet; otherwise a found key has its
+// LRU/LFU recency/frequency bookkeeping updated.
 func (m *IntStrMap) Get(key int) string {
 	m.mu.RLock()
-	val, _ := m.data[key]
+	val, ok := m.data[key]
+	hasEvict := m.evict != nil
 	m.mu.RUnlock()
+	if ok && hasEvict {
+		if m.touchAccess(key) {
+			return ""
+		}
+	}
 	return val
 }
 
 // Pop retrieves and deletes an item from the map.
 func (m *IntStrMap) Pop() (key int, value string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	found := false
 	for key, value = range m.data {
 		delete(m.data, key)
-		return
+		found = true
+		break
+	}
+	m.mu.Unlock()
+	if found {
+		m.notify(Event{Type: EventTypeRemove, Key: key, OldValue: value})
 	}
 	return
 }
@@ -154,23 +229,27 @@ func (m *IntStrMap) Pop() (key int, value string) {
 // It returns all items if size == -1.
 func (m *IntStrMap) Pops(size int) map[int]string {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	if size > len(m.data) || size == -1 {
 		size = len(m.data)
 	}
 	if size == 0 {
+		m.mu.Unlock()
 		return nil
 	}
 	index := 0
 	newMap := make(map[int]string, size)
+	events := make([]Event, 0, size)
 	for k, v := range m.data {
 		delete(m.data, k)
 		newMap[k] = v
+		events = append(events, Event{Type: EventTypeRemove, Key: k, OldValue: v})
 		index++
 		if index == size {
 			break
 		}
 	}
+	m.mu.Unlock()
+	m.notify(events...)
 	return newMap
 }
 
@@ -179,13 +258,30 @@ func (m *IntStrMap) Pops(size int) map[int]string {
 // or else just return the existing value.
 //
 // It returns value with given <key>.
+//
+// If the map was created with NewIntStrMapWithEviction, inserting a new key
+// while already at MaxSize evicts one entry first, per the configured
+// EvictionPolicy, same as Set.
 func (m *IntStrMap) doSetWithLockCheck(key int, value string) string {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	if v, ok := m.data[key]; ok {
+		m.mu.Unlock()
 		return v
 	}
+	evictedKey, evictedVal, evicted, onEvict := m.evictAndTouchOnInsert(key)
 	m.data[key] = value
+	m.mu.Unlock()
+	if evicted {
+		m.notify(
+			Event{Type: EventTypeRemove, Key: evictedKey, OldValue: evictedVal},
+			Event{Type: EventTypeSet, Key: key, NewValue: value},
+		)
+	} else {
+		m.notify(Event{Type: EventTypeSet, Key: key, NewValue: value})
+	}
+	if evicted && onEvict != nil {
+		onEvict(evictedKey, evictedVal, EvictReasonCapacity)
+	}
 	return value
 }
 
@@ -215,20 +311,38 @@ func (m *IntStrMap) GetOrSetFunc(key int, f func() string) string {
 // GetOrSetFuncLock differs with GetOrSetFunc function is that it executes function <f>
 // with mutex.Lock of the hash map.
 func (m *IntStrMap) GetOrSetFuncLock(key int, f func() string) string {
-	if v, ok := m.Search(key); !ok {
-		m.mu.Lock()
-		defer m.mu.Unlock()
-		if v, ok = m.data[key]; ok {
-			return v
-		}
-		v = f()
-		if v != "" {
-			m.data[key] = v
-		}
+	if v, ok := m.Search(key); ok {
 		return v
-	} else {
+	}
+	m.mu.Lock()
+	if v, ok := m.data[key]; ok {
+		m.mu.Unlock()
 		return v
 	}
+	v := f()
+	var evictedKey int
+	var evictedVal string
+	var evicted bool
+	var onEvict func(key int, val string, reason EvictReason)
+	if v != "" {
+		evictedKey, evictedVal, evicted, onEvict = m.evictAndTouchOnInsert(key)
+		m.data[key] = v
+	}
+	m.mu.Unlock()
+	if v != "" {
+		if evicted {
+			m.notify(
+				Event{Type: EventTypeRemove, Key: evictedKey, OldValue: evictedVal},
+				Event{Type: EventTypeSet, Key: key, NewValue: v},
+			)
+		} else {
+			m.notify(Event{Type: EventTypeSet, Key: key, NewValue: v})
+		}
+		if evicted && onEvict != nil {
+			onEvict(evictedKey, evictedVal, EvictReasonCapacity)
+		}
+	}
+	return v
 }
 
 // SetIfNotExist sets <value> to the map if the <key> does not exist, then return true.
@@ -257,24 +371,54 @@ func (m *IntStrMap) SetIfNotExistFunc(key int, f func() string) bool {
 // SetIfNotExistFuncLock differs with SetIfNotExistFunc function is that
 // it executes function <f> with mutex.Lock of the hash map.
 func (m *IntStrMap) SetIfNotExistFuncLock(key int, f func() string) bool {
-	if !m.Contains(key) {
-		m.mu.Lock()
-		defer m.mu.Unlock()
-		if _, ok := m.data[key]; !ok {
-			m.data[key] = f()
+	if m.Contains(key) {
+		return false
+	}
+	m.mu.Lock()
+	var v string
+	inserted := false
+	var evictedKey int
+	var evictedVal string
+	var evicted bool
+	var onEvict func(key int, val string, reason EvictReason)
+	if _, ok := m.data[key]; !ok {
+		v = f()
+		evictedKey, evictedVal, evicted, onEvict = m.evictAndTouchOnInsert(key)
+		m.data[key] = v
+		inserted = true
+	}
+	m.mu.Unlock()
+	if inserted {
+		if evicted {
+			m.notify(
+				Event{Type: EventTypeRemove, Key: evictedKey, OldValue: evictedVal},
+				Event{Type: EventTypeSet, Key: key, NewValue: v},
+			)
+		} else {
+			m.notify(Event{Type: EventTypeSet, Key: key, NewValue: v})
+		}
+		if evicted && onEvict != nil {
+			onEvict(evictedKey, evictedVal, EvictReasonCapacity)
 		}
-		return true
 	}
-	return false
+	return true
 }
 
 // Removes batch deletes values of the map by keys.
 func (m *IntStrMap) Removes(keys []int) {
 	m.mu.Lock()
+	events := make([]Event, 0, len(keys))
 	for _, key := range keys {
-		delete(m.data, key)
+		if val, exists := m.data[key]; exists {
+			delete(m.data, key)
+			events = append(events, Event{Type: EventTypeRemove, Key: key, OldValue: val})
+		}
+		if m.evict != nil {
+			m.evict.forget(key)
+		}
 	}
 	m.mu.Unlock()
+	m.notify(events...)
 }
 
 // Remove deletes value from map by given <key>, and return this deleted value.
@@ -283,8 +427,14 @@ func (m *IntStrMap) Remove(key int) string {
 	val, exists := m.data[key]
 	if exists {
 		delete(m.data, key)
+		if m.evict != nil {
+			m.evict.forget(key)
+		}
 	}
 	m.mu.Unlock()
+	if exists {
+		m.notify(Event{Type: EventTypeRemove, Key: key, OldValue: val})
+	}
 	return val
 }
 
@@ -314,6 +464,63 @@ func (m *IntStrMap) Values() []string {
 	return values
 }
 
+// ContainsValue checks whether a value exists in the map.
+// It returns true if <value> is found, or else false.
+func (m *IntStrMap) ContainsValue(value string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, v := range m.data {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Replace atomically replaces the underlying data of the map with <data>.
+func (m *IntStrMap) Replace(data map[int]string) {
+	m.mu.Lock()
+	hasWatch := m.watch != nil
+	var old map[int]string
+	if hasWatch {
+		old = m.data
+	}
+	m.data = data
+	m.mu.Unlock()
+	if hasWatch {
+		m.notify(diffIntStrMap(old, data)...)
+	}
+}
+
+// Equal checks whether the two maps are equal, i.e. they have the same size
+// and the same set of key-value pairs.
+//
+// To avoid deadlock when both maps are concurrent-safe, their mutexes are
+// acquired in ascending order of their memory address rather than in call
+// order.
+func (m *IntStrMap) Equal(other *IntStrMap) bool {
+	if m == other {
+		return true
+	}
+	first, second := m, other
+	if uintptr(unsafe.Pointer(m)) > uintptr(unsafe.Pointer(other)) {
+		first, second = other, m
+	}
+	first.mu.RLock()
+	defer first.mu.RUnlock()
+	second.mu.RLock()
+	defer second.mu.RUnlock()
+	if len(m.data) != len(other.data) {
+		return false
+	}
+	for k, v := range m.data {
+		if ov, ok := other.data[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
 // Contains checks whether a key exists.
 // It returns true if the <key> exists, or else false.
 func (m *IntStrMap) Contains(key int) bool {
@@ -341,14 +548,42 @@ func (m *IntStrMap) IsEmpty() bool {
 func (m *IntStrMap) Clear() {
 	m.mu.Lock()
 	m.data = make(map[int]string)
+	if m.evict != nil {
+		old := m.evict
+		close(old.stopCh)
+		m.evict = nil
+		if old.onEvict != nil || old.maxSize > 0 || old.policy != EvictionPolicyNone {
+			m.evict = newIntStrMapEviction()
+			m.evict.onEvict = old.onEvict
+			m.evict.maxSize = old.maxSize
+			m.evict.policy = old.policy
+		}
+	}
 	m.mu.Unlock()
+	m.notify(Event{Type: EventTypeClear})
 }
 
 // LockFunc locks writing with given callback function <f> within RWMutex.Lock.
+//
+// If the map has watchers, the data is diffed before and after <f> runs so
+// that any keys <f> added, changed or removed are still reported as Events.
 func (m *IntStrMap) LockFunc(f func(m map[int]string)) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	hasWatch := m.watch != nil
+	var before map[int]string
+	if hasWatch {
+		before = make(map[int]string, len(m.data))
+		for k, v := range m.data {
+			before[k] = v
+		}
+	}
 	f(m.data)
+	var events []Event
+	if hasWatch {
+		events = diffIntStrMap(before, m.data)
+	}
+	m.mu.Unlock()
+	m.notify(events...)
 }
 
 // RLockFunc locks reading with given callback function <f> within RWMutex.RLock.
@@ -361,33 +596,58 @@ func (m *IntStrMap) RLockFunc(f func(m map[int]string)) {
 // Flip exchanges key-value of the map to value-key.
 func (m *IntStrMap) Flip() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	n := make(map[int]string, len(m.data))
+	events := make([]Event, 0, 2*len(m.data))
 	for k, v := range m.data {
 		n[gconv.Int(v)] = gconv.String(k)
+		events = append(events, Event{Type: EventTypeRemove, Key: k, OldValue: v})
+	}
+	for k, v := range n {
+		events = append(events, Event{Type: EventTypeSet, Key: k, NewValue: v})
 	}
 	m.data = n
+	m.mu.Unlock()
+	m.notify(events...)
 }
 
 // Merge merges two hash maps.
 // The <other> map will be merged into the map <m>.
 func (m *IntStrMap) Merge(other *IntStrMap) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	if other != m {
 		other.mu.RLock()
-		defer other.mu.RUnlock()
 	}
+	events := make([]Event, 0, len(other.data))
 	for k, v := range other.data {
+		events = append(events, Event{Type: EventTypeSet, Key: k, OldValue: m.data[k], NewValue: v})
 		m.data[k] = v
 	}
+	if other != m {
+		other.mu.RUnlock()
+	}
+	m.mu.Unlock()
+	m.notify(events...)
 }
 
 // MarshalJSON implements the interface MarshalJSON for json.Marshal.
+//
+// Note that if the map was created with TTL support, entries that have
+// already expired but not yet swept are excluded from the result.
 func (m *IntStrMap) MarshalJSON() ([]byte, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return json.Marshal(m.data)
+	if m.evict == nil || m.evict.heap.Len() == 0 {
+		return json.Marshal(m.data)
+	}
+	data := make(map[int]string, len(m.data))
+	now := time.Now()
+	for k, v := range m.data {
+		if item, ok := m.evict.items[k]; ok && !item.deadline.After(now) {
+			continue
+		}
+		data[k] = v
+	}
+	return json.Marshal(data)
 }
 
 // UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal.
@@ -397,9 +657,15 @@ func (m *IntStrMap) UnmarshalJSON(b []byte) error {
 		m.data = make(map[int]string)
 	}
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	if err := json.Unmarshal(b, &m.data); err != nil {
+		m.mu.Unlock()
 		return err
 	}
+	events := make([]Event, 0, len(m.data))
+	for k, v := range m.data {
+		events = append(events, Event{Type: EventTypeSet, Key: k, NewValue: v})
+	}
+	m.mu.Unlock()
+	m.notify(events...)
 	return nil
 }
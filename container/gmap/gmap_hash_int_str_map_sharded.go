@@ -0,0 +1,415 @@
+// Copyright 2017 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gogf/gf/internal/empty"
+)
+
+// defaultShardedIntStrMapShardCount is the default shard count of ShardedIntStrMap,
+// which must be a power of two so that the shard index can be computed with a bitmask.
+const defaultShardedIntStrMapShardCount = 32
+
+// intStrMapShard is one stripe of a ShardedIntStrMap. It keeps its own lock so that
+// operations against different shards never contend with each other.
+type intStrMapShard struct {
+	mu   sync.RWMutex
+	data map[int]string
+}
+
+// ShardedIntStrMap is a sharded, concurrency-safe hash map of int => string.
+// Instead of guarding the whole map with a single rwmutex.RWMutex as IntStrMap does,
+// it stripes the key space across N independent shards so that concurrent writers
+// touching different shards never block each other. It is intended as a drop-in
+// alternative to IntStrMap for write-heavy workloads under high concurrency.
+//
+// Its API mirrors IntStrMap's core methods; it has no Pops, MapStrAny, Flip,
+// ContainsValue, Replace or Equal, it does not carry IntStrMap's TTL/eviction
+// (NewIntStrMapWithEviction) or Watch/BatchWatch layers, and siblings for the other
+// gmap types (StrStrMap, AnyAnyMap, ...) are not provided since this tree only
+// contains IntStrMap to begin with.
+type ShardedIntStrMap struct {
+	shards []*intStrMapShard
+	mask   uint32
+}
+
+// NewShardedIntStrMap returns an empty ShardedIntStrMap object.
+// The parameter <shardCount> specifies the number of shards, which must be a power
+// of two; it defaults to 32 when not given or when an invalid value is passed.
+func NewShardedIntStrMap(shardCount ...int) *ShardedIntStrMap {
+	count := defaultShardedIntStrMapShardCount
+	if len(shardCount) > 0 && shardCount[0] > 0 && shardCount[0]&(shardCount[0]-1) == 0 {
+		count = shardCount[0]
+	}
+	m := &ShardedIntStrMap{
+		shards: make([]*intStrMapShard, count),
+		mask:   uint32(count - 1),
+	}
+	for i := 0; i < count; i++ {
+		m.shards[i] = &intStrMapShard{
+			data: make(map[int]string),
+		}
+	}
+	return m
+}
+
+// shard returns the shard responsible for the given <key> after mixing it,
+// so that sequential keys do not pile up on the same shard.
+func (m *ShardedIntStrMap) shard(key int) *intStrMapShard {
+	h := uint32(key) * 0x9E3779B1
+	return m.shards[h&m.mask]
+}
+
+// Set sets key-value to the hash map.
+func (m *ShardedIntStrMap) Set(key int, val string) {
+	s := m.shard(key)
+	s.mu.Lock()
+	s.data[key] = val
+	s.mu.Unlock()
+}
+
+// Sets batch sets key-values to the hash map.
+func (m *ShardedIntStrMap) Sets(data map[int]string) {
+	for k, v := range data {
+		m.Set(k, v)
+	}
+}
+
+// Search searches the map with given <key>.
+// Second return parameter <found> is true if key was found, otherwise false.
+func (m *ShardedIntStrMap) Search(key int) (value string, found bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	value, found = s.data[key]
+	s.mu.RUnlock()
+	return
+}
+
+// Get returns the value by given <key>.
+func (m *ShardedIntStrMap) Get(key int) string {
+	value, _ := m.Search(key)
+	return value
+}
+
+// Pop retrieves and deletes an item from the map, chosen from a non-empty shard.
+func (m *ShardedIntStrMap) Pop() (key int, value string) {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		for key, value = range s.data {
+			delete(s.data, key)
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+	}
+	return
+}
+
+// doSetWithLockCheck checks whether value of the key exists with the shard's mutex.Lock,
+// if not exists, set value to the map with given <key>, or else just return the
+// existing value. It returns value with given <key>.
+func (m *ShardedIntStrMap) doSetWithLockCheck(key int, value string) string {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.data[key]; ok {
+		return v
+	}
+	s.data[key] = value
+	return value
+}
+
+// GetOrSet returns the value by key,
+// or set value with given <value> if not exist and returns this value.
+func (m *ShardedIntStrMap) GetOrSet(key int, value string) string {
+	if v, ok := m.Search(key); !ok {
+		return m.doSetWithLockCheck(key, value)
+	} else {
+		return v
+	}
+}
+
+// GetOrSetFunc returns the value by key,
+// or sets value with return value of callback function <f> if not exist and returns this value.
+func (m *ShardedIntStrMap) GetOrSetFunc(key int, f func() string) string {
+	if v, ok := m.Search(key); !ok {
+		return m.doSetWithLockCheck(key, f())
+	} else {
+		return v
+	}
+}
+
+// SetIfNotExist sets <value> to the map if the <key> does not exist, then return true.
+// It returns false if <key> exists, and <value> would be ignored.
+func (m *ShardedIntStrMap) SetIfNotExist(key int, value string) bool {
+	if !m.Contains(key) {
+		m.doSetWithLockCheck(key, value)
+		return true
+	}
+	return false
+}
+
+// SetIfNotExistFunc sets value with return value of callback function <f>, then return true.
+// It returns false if <key> exists, and <value> would be ignored.
+func (m *ShardedIntStrMap) SetIfNotExistFunc(key int, f func() string) bool {
+	if !m.Contains(key) {
+		m.doSetWithLockCheck(key, f())
+		return true
+	}
+	return false
+}
+
+// SetIfNotExistFuncLock sets value with return value of callback function <f>, then return true.
+// It returns false if <key> exists, and <value> would be ignored.
+//
+// SetIfNotExistFuncLock differs with SetIfNotExistFunc function is that
+// it executes function <f> with the shard's mutex.Lock held.
+func (m *ShardedIntStrMap) SetIfNotExistFuncLock(key int, f func() string) bool {
+	if !m.Contains(key) {
+		s := m.shard(key)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.data[key]; !ok {
+			s.data[key] = f()
+		}
+		return true
+	}
+	return false
+}
+
+// GetOrSetFuncLock returns the value by key,
+// or sets value with return value of callback function <f> if not exist and returns this value.
+//
+// GetOrSetFuncLock executes function <f> with the shard's mutex.Lock held, consistent
+// with IntStrMap.GetOrSetFuncLock.
+func (m *ShardedIntStrMap) GetOrSetFuncLock(key int, f func() string) string {
+	if v, ok := m.Search(key); ok {
+		return v
+	}
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.data[key]; ok {
+		return v
+	}
+	v := f()
+	if v != "" {
+		s.data[key] = v
+	}
+	return v
+}
+
+// Remove deletes value from map by given <key>, and return this deleted value.
+func (m *ShardedIntStrMap) Remove(key int) string {
+	s := m.shard(key)
+	s.mu.Lock()
+	val := s.data[key]
+	delete(s.data, key)
+	s.mu.Unlock()
+	return val
+}
+
+// Removes batch deletes values of the map by keys.
+func (m *ShardedIntStrMap) Removes(keys []int) {
+	for _, key := range keys {
+		m.Remove(key)
+	}
+}
+
+// Contains checks whether a key exists.
+// It returns true if the <key> exists, or else false.
+func (m *ShardedIntStrMap) Contains(key int) bool {
+	_, found := m.Search(key)
+	return found
+}
+
+// Iterator iterates the hash map with custom callback function <f>, shard by shard.
+// It never holds a map-wide lock: each shard is locked only while it is being visited.
+// If <f> returns false, the iteration stops at once, without visiting further shards.
+func (m *ShardedIntStrMap) Iterator(f func(k int, v string) bool) {
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for k, v := range s.data {
+			if !f(k, v) {
+				s.mu.RUnlock()
+				return
+			}
+		}
+		s.mu.RUnlock()
+	}
+}
+
+// Size returns the size of the map, fanning out across all shards.
+func (m *ShardedIntStrMap) Size() int {
+	length := 0
+	for _, s := range m.shards {
+		s.mu.RLock()
+		length += len(s.data)
+		s.mu.RUnlock()
+	}
+	return length
+}
+
+// IsEmpty checks whether the map is empty.
+// It returns true if map is empty, or else false.
+func (m *ShardedIntStrMap) IsEmpty() bool {
+	return m.Size() == 0
+}
+
+// Keys returns all keys of the map as a slice, fanning out across all shards.
+func (m *ShardedIntStrMap) Keys() []int {
+	keys := make([]int, 0, m.Size())
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for k := range s.data {
+			keys = append(keys, k)
+		}
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// Values returns all values of the map as a slice, fanning out across all shards.
+func (m *ShardedIntStrMap) Values() []string {
+	values := make([]string, 0, m.Size())
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for _, v := range s.data {
+			values = append(values, v)
+		}
+		s.mu.RUnlock()
+	}
+	return values
+}
+
+// Map returns a copy of the underlying data of the map, merged from all shards.
+func (m *ShardedIntStrMap) Map() map[int]string {
+	data := make(map[int]string, m.Size())
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for k, v := range s.data {
+			data[k] = v
+		}
+		s.mu.RUnlock()
+	}
+	return data
+}
+
+// Clear deletes all data of the map, it will remake each shard's underlying data map.
+func (m *ShardedIntStrMap) Clear() {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		s.data = make(map[int]string)
+		s.mu.Unlock()
+	}
+}
+
+// MapCopy returns a copy of the data of the hash map, merged from all shards.
+func (m *ShardedIntStrMap) MapCopy() map[int]string {
+	return m.Map()
+}
+
+// Clone returns a new ShardedIntStrMap, with the same shard count, holding a
+// copy of the current map data.
+func (m *ShardedIntStrMap) Clone() *ShardedIntStrMap {
+	n := NewShardedIntStrMap(len(m.shards))
+	n.Sets(m.Map())
+	return n
+}
+
+// Merge merges two sharded hash maps.
+// The <other> map will be merged into the map <m>.
+func (m *ShardedIntStrMap) Merge(other *ShardedIntStrMap) {
+	if other != m {
+		m.Sets(other.Map())
+	}
+}
+
+// FilterEmpty deletes all key-value pair of which the value is empty.
+func (m *ShardedIntStrMap) FilterEmpty() {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		for k, v := range s.data {
+			if empty.IsEmpty(v) {
+				delete(s.data, k)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// LockFunc locks writing with given callback function <f>.
+//
+// Unlike IntStrMap.LockFunc, this locks every shard for the duration of <f>
+// and hands it a merged view of the whole map, so it loses the sharded map's
+// usual lock-splitting benefit; any changes <f> makes are written back to
+// their owning shards once it returns.
+func (m *ShardedIntStrMap) LockFunc(f func(m map[int]string)) {
+	for _, s := range m.shards {
+		s.mu.Lock()
+	}
+	defer func() {
+		for _, s := range m.shards {
+			s.mu.Unlock()
+		}
+	}()
+	merged := make(map[int]string)
+	for _, s := range m.shards {
+		for k, v := range s.data {
+			merged[k] = v
+		}
+	}
+	f(merged)
+	for _, s := range m.shards {
+		s.data = make(map[int]string)
+	}
+	for k, v := range merged {
+		s := m.shard(k)
+		s.data[k] = v
+	}
+}
+
+// RLockFunc locks reading with given callback function <f>, handing it a
+// merged, point-in-time view of the whole map across all shards.
+func (m *ShardedIntStrMap) RLockFunc(f func(m map[int]string)) {
+	for _, s := range m.shards {
+		s.mu.RLock()
+	}
+	merged := make(map[int]string)
+	for _, s := range m.shards {
+		for k, v := range s.data {
+			merged[k] = v
+		}
+	}
+	for _, s := range m.shards {
+		s.mu.RUnlock()
+	}
+	f(merged)
+}
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal.
+func (m *ShardedIntStrMap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Map())
+}
+
+// UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal.
+func (m *ShardedIntStrMap) UnmarshalJSON(b []byte) error {
+	data := make(map[int]string)
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if m.shards == nil {
+		*m = *NewShardedIntStrMap()
+	}
+	for k, v := range data {
+		m.Set(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,507 @@
+// Copyright 2017 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"container/heap"
+	"container/list"
+	"time"
+)
+
+// EvictReason describes why an entry was removed by the eviction layer.
+type EvictReason int
+
+const (
+	// EvictReasonExpired means the entry was removed because its TTL elapsed.
+	EvictReasonExpired EvictReason = iota
+	// EvictReasonCapacity means the entry was removed to make room under MaxSize.
+	EvictReasonCapacity
+	// EvictReasonManual names removal by an explicit call. No method of
+	// IntStrMap reports it: Remove, Removes and Clear do not invoke OnEvict.
+	EvictReasonManual
+)
+
+// EvictionPolicy decides which entry to drop when a size-limited map is full.
+type EvictionPolicy int
+
+const (
+	// EvictionPolicyNone disables capacity-based eviction; MaxSize is ignored.
+	EvictionPolicyNone EvictionPolicy = iota
+	// EvictionPolicyLRU evicts the least-recently-used entry first.
+	EvictionPolicyLRU
+	// EvictionPolicyLFU evicts the least-frequently-used entry first.
+	EvictionPolicyLFU
+)
+
+// EvictionOption configures a map created by NewIntStrMapWithEviction.
+type EvictionOption func(*intStrMapEviction)
+
+// EvictionMaxSize caps the map at <size> entries. Once the cap is reached,
+// the configured EvictionPolicy decides which entry makes room for a new one.
+//
+// The cap is enforced by Set, SetWithTTL and the GetOrSet/SetIfNotExist
+// family, and only keys written through them are eviction candidates. Sets,
+// Merge, Replace, LockFunc and UnmarshalJSON write without evicting, so they
+// can take the map past <size>.
+func EvictionMaxSize(size int) EvictionOption {
+	return func(e *intStrMapEviction) {
+		e.maxSize = size
+	}
+}
+
+// EvictionWithPolicy sets the eviction policy used once MaxSize is reached.
+func EvictionWithPolicy(policy EvictionPolicy) EvictionOption {
+	return func(e *intStrMapEviction) {
+		e.policy = policy
+	}
+}
+
+// ttlItem is one entry of the expiration min-heap, ordered by <deadline>.
+type ttlItem struct {
+	key      int
+	deadline time.Time
+	index    int
+}
+
+// ttlHeap is a container/heap.Interface ordering ttlItem by soonest deadline.
+type ttlHeap []*ttlItem
+
+func (h ttlHeap) Len() int            { return len(h) }
+func (h ttlHeap) Less(i, j int) bool  { return h[i].deadline.Before(h[j].deadline) }
+func (h ttlHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *ttlHeap) Push(x interface{}) {
+	item := x.(*ttlItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+func (h *ttlHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// intStrMapEviction holds the optional TTL/size-eviction state of an IntStrMap.
+// All of its fields are guarded by the owning map's <mu>.
+type intStrMapEviction struct {
+	onEvict func(key int, val string, reason EvictReason)
+	maxSize int
+	policy  EvictionPolicy
+
+	heap  ttlHeap
+	items map[int]*ttlItem
+
+	lruList *list.List
+	lruElem map[int]*list.Element
+
+	lfuFreq map[int]int
+
+	// running is true while the sweeper goroutine for this eviction state is
+	// alive. It is guarded by the owning map's <mu>, the same lock the
+	// sweeper itself takes before deciding whether to keep running, so a
+	// new TTL entry can never be added in the gap between the sweeper
+	// deciding to exit and it actually exiting.
+	running bool
+
+	wakeCh chan struct{}
+	stopCh chan struct{}
+}
+
+func newIntStrMapEviction() *intStrMapEviction {
+	return &intStrMapEviction{
+		items:   make(map[int]*ttlItem),
+		lruList: list.New(),
+		lruElem: make(map[int]*list.Element),
+		lfuFreq: make(map[int]int),
+		wakeCh:  make(chan struct{}, 1),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// wake nudges the sweeper goroutine to recompute its sleep duration,
+// for example after a sooner deadline was just inserted.
+func (e *intStrMapEviction) wake() {
+	select {
+	case e.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// setTTL installs or replaces the expiration deadline for <key>.
+func (e *intStrMapEviction) setTTL(key int, ttl time.Duration) {
+	deadline := time.Now().Add(ttl)
+	if item, ok := e.items[key]; ok {
+		item.deadline = deadline
+		heap.Fix(&e.heap, item.index)
+	} else {
+		item := &ttlItem{key: key, deadline: deadline}
+		heap.Push(&e.heap, item)
+		e.items[key] = item
+	}
+	e.wake()
+}
+
+// removeTTL drops any expiration tracking for <key>, e.g. because it was deleted.
+func (e *intStrMapEviction) removeTTL(key int) {
+	if item, ok := e.items[key]; ok {
+		heap.Remove(&e.heap, item.index)
+		delete(e.items, key)
+	}
+}
+
+// ttlOf returns the remaining time-to-live of <key>, or zero if it carries no TTL.
+func (e *intStrMapEviction) ttlOf(key int) time.Duration {
+	item, ok := e.items[key]
+	if !ok {
+		return 0
+	}
+	if d := time.Until(item.deadline); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// touchLRU moves <key> to the most-recently-used end of the LRU list.
+func (e *intStrMapEviction) touchLRU(key int) {
+	if elem, ok := e.lruElem[key]; ok {
+		e.lruList.MoveToFront(elem)
+	} else {
+		e.lruElem[key] = e.lruList.PushFront(key)
+	}
+}
+
+// touchLFU bumps the usage counter of <key> for the LFU policy.
+func (e *intStrMapEviction) touchLFU(key int) {
+	e.lfuFreq[key]++
+}
+
+// forget discards all bookkeeping the eviction layer keeps about <key>.
+func (e *intStrMapEviction) forget(key int) {
+	e.removeTTL(key)
+	if elem, ok := e.lruElem[key]; ok {
+		e.lruList.Remove(elem)
+		delete(e.lruElem, key)
+	}
+	delete(e.lfuFreq, key)
+}
+
+// candidateForCapacity returns the key the configured policy would evict next
+// to make room for a new entry, or false if there is no candidate.
+func (e *intStrMapEviction) candidateForCapacity() (int, bool) {
+	switch e.policy {
+	case EvictionPolicyLRU:
+		if back := e.lruList.Back(); back != nil {
+			return back.Value.(int), true
+		}
+	case EvictionPolicyLFU:
+		best, found := 0, false
+		bestFreq := 0
+		for k, f := range e.lfuFreq {
+			if !found || f < bestFreq {
+				best, bestFreq, found = k, f, true
+			}
+		}
+		return best, found
+	}
+	return 0, false
+}
+
+// startSweeper launches the background goroutine that removes expired
+// entries for eviction state <e>. The caller must have just set e.running =
+// true while holding <m.mu>; startSweeper itself exits, clearing e.running,
+// as soon as the heap drains or Clear has replaced the map's eviction state,
+// instead of blocking or spinning forever. This keeps at
+// most one live sweeper per eviction state: the next SetWithTTL/Expire call
+// sees e.running == false (under the same lock the sweeper used to clear
+// it) and starts a fresh one, rather than piling up goroutines.
+func (m *IntStrMap) startSweeper() {
+	e := m.evict
+	go func() {
+		for {
+			m.mu.Lock()
+			if e.heap.Len() == 0 || m.evict != e {
+				e.running = false
+				m.mu.Unlock()
+				return
+			}
+			wait := time.Until(e.heap[0].deadline)
+			m.mu.Unlock()
+
+			if wait <= 0 {
+				m.sweepExpired()
+				continue
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+				m.sweepExpired()
+			case <-e.wakeCh:
+				timer.Stop()
+			case <-e.stopCh:
+				timer.Stop()
+				m.mu.Lock()
+				e.running = false
+				m.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// sweepExpired removes every entry whose deadline has passed, firing OnEvict
+// for each of them with EvictReasonExpired.
+func (m *IntStrMap) sweepExpired() {
+	m.mu.Lock()
+	e := m.evict
+	if e == nil {
+		m.mu.Unlock()
+		return
+	}
+	var evicted []struct {
+		key int
+		val string
+	}
+	now := time.Now()
+	for e.heap.Len() > 0 && !e.heap[0].deadline.After(now) {
+		item := heap.Pop(&e.heap).(*ttlItem)
+		delete(e.items, item.key)
+		if v, ok := m.data[item.key]; ok {
+			delete(m.data, item.key)
+			e.forget(item.key)
+			evicted = append(evicted, struct {
+				key int
+				val string
+			}{item.key, v})
+		}
+	}
+	onEvict := e.onEvict
+	m.mu.Unlock()
+	if len(evicted) > 0 {
+		events := make([]Event, len(evicted))
+		for i, item := range evicted {
+			events[i] = Event{Type: EventTypeRemove, Key: item.key, OldValue: item.val}
+		}
+		m.notify(events...)
+	}
+	if onEvict != nil {
+		for _, item := range evicted {
+			onEvict(item.key, item.val, EvictReasonExpired)
+		}
+	}
+}
+
+// NewIntStrMapWithEviction returns an empty, concurrency-safe IntStrMap usable
+// as a lightweight in-process cache: entries may carry a TTL (see SetWithTTL)
+// and, once <EvictionMaxSize> is reached, the configured EvictionPolicy decides
+// which entry is dropped to make room for a new one.
+func NewIntStrMapWithEviction(opts ...EvictionOption) *IntStrMap {
+	m := NewIntStrMap(true)
+	e := newIntStrMapEviction()
+	for _, opt := range opts {
+		opt(e)
+	}
+	m.evict = e
+	return m
+}
+
+// OnEvict registers a callback invoked whenever an entry is evicted, whether
+// due to TTL expiration or to capacity pressure. It must be set before the
+// eviction happens to take effect; it is not retroactive.
+func (m *IntStrMap) OnEvict(f func(key int, val string, reason EvictReason)) {
+	m.mu.Lock()
+	if m.evict == nil {
+		m.evict = newIntStrMapEviction()
+	}
+	m.evict.onEvict = f
+	m.mu.Unlock()
+}
+
+// evictForCapacity drops one entry to make room under maxSize, if configured
+// and if the map is already at capacity. It must be called with <mu> held.
+func (m *IntStrMap) evictForCapacity() (evictedKey int, evictedVal string, ok bool) {
+	e := m.evict
+	if e == nil || e.maxSize <= 0 || e.policy == EvictionPolicyNone || len(m.data) < e.maxSize {
+		return 0, "", false
+	}
+	for {
+		key, found := e.candidateForCapacity()
+		if !found {
+			return 0, "", false
+		}
+		// Skip bookkeeping left behind for keys already gone from <m.data>.
+		val, exists := m.data[key]
+		e.forget(key)
+		if exists {
+			delete(m.data, key)
+			return key, val, true
+		}
+	}
+}
+
+// evictAndTouchOnInsert performs the same capacity-eviction and LRU/LFU
+// bookkeeping Set does for a brand-new key, for the GetOrSet/SetIfNotExist
+// family of insert paths. It must be called with <mu> held, before <key> is
+// written into <m.data>.
+func (m *IntStrMap) evictAndTouchOnInsert(key int) (evictedKey int, evictedVal string, evicted bool, onEvict func(key int, val string, reason EvictReason)) {
+	if m.evict == nil {
+		return 0, "", false, nil
+	}
+	evictedKey, evictedVal, evicted = m.evictForCapacity()
+	switch m.evict.policy {
+	case EvictionPolicyLRU:
+		m.evict.touchLRU(key)
+	case EvictionPolicyLFU:
+		m.evict.touchLFU(key)
+	}
+	onEvict = m.evict.onEvict
+	return
+}
+
+// SetWithTTL sets key-value to the hash map and expires it after <ttl>.
+// It starts the background sweeper goroutine whenever none is running: on the
+// first TTL entry, and again after an earlier sweeper exited on a drained heap.
+func (m *IntStrMap) SetWithTTL(key int, val string, ttl time.Duration) {
+	m.mu.Lock()
+	if m.evict == nil {
+		m.evict = newIntStrMapEviction()
+	}
+	e := m.evict
+	startSweeper := !e.running
+	if startSweeper {
+		e.running = true
+	}
+	old, existed := m.data[key]
+	var evictedKey int
+	var evictedVal string
+	var evicted bool
+	if !existed {
+		evictedKey, evictedVal, evicted = m.evictForCapacity()
+	}
+	m.data[key] = val
+	e.setTTL(key, ttl)
+	if e.policy == EvictionPolicyLRU {
+		e.touchLRU(key)
+	} else if e.policy == EvictionPolicyLFU {
+		e.touchLFU(key)
+	}
+	onEvict := e.onEvict
+	m.mu.Unlock()
+	if startSweeper {
+		m.startSweeper()
+	}
+	if evicted && onEvict != nil {
+		onEvict(evictedKey, evictedVal, EvictReasonCapacity)
+	}
+	if evicted {
+		m.notify(
+			Event{Type: EventTypeRemove, Key: evictedKey, OldValue: evictedVal},
+			Event{Type: EventTypeSet, Key: key, OldValue: old, NewValue: val},
+		)
+	} else {
+		ev := Event{Type: EventTypeSet, Key: key, NewValue: val}
+		if existed {
+			ev.OldValue = old
+		}
+		m.notify(ev)
+	}
+}
+
+// GetOrSetFuncWithTTL returns the value by key, or sets value with the return
+// value of callback function <f> and the given <ttl> if not exist, and returns
+// this value.
+func (m *IntStrMap) GetOrSetFuncWithTTL(key int, f func() string, ttl time.Duration) string {
+	if v, ok := m.Search(key); ok {
+		return v
+	}
+	v := f()
+	m.SetWithTTL(key, v, ttl)
+	return v
+}
+
+// Expire updates the TTL of an existing <key>. It has no effect if the key
+// does not exist in the map.
+func (m *IntStrMap) Expire(key int, ttl time.Duration) {
+	m.mu.Lock()
+	if _, ok := m.data[key]; !ok {
+		m.mu.Unlock()
+		return
+	}
+	if m.evict == nil {
+		m.evict = newIntStrMapEviction()
+	}
+	startSweeper := !m.evict.running
+	if startSweeper {
+		m.evict.running = true
+	}
+	m.evict.setTTL(key, ttl)
+	m.mu.Unlock()
+	if startSweeper {
+		m.startSweeper()
+	}
+}
+
+// touchAccess is called after a successful read of <key>, if the map has
+// eviction configured. Expirations are swept lazily: if <key>'s TTL has
+// already elapsed but the background sweeper hasn't reached it yet, touchAccess
+// removes it right now and reports it as expired, so a read can never observe
+// a stale value just because it landed between the deadline and the next
+// sweep; otherwise it updates <key>'s LRU/LFU bookkeeping.
+func (m *IntStrMap) touchAccess(key int) (expired bool) {
+	m.mu.Lock()
+	e := m.evict
+	if e == nil {
+		m.mu.Unlock()
+		return false
+	}
+	if item, ok := e.items[key]; ok && !item.deadline.After(time.Now()) {
+		val, existed := m.data[key]
+		if existed {
+			delete(m.data, key)
+		}
+		e.forget(key)
+		onEvict := e.onEvict
+		m.mu.Unlock()
+		if existed {
+			m.notify(Event{Type: EventTypeRemove, Key: key, OldValue: val})
+			if onEvict != nil {
+				onEvict(key, val, EvictReasonExpired)
+			}
+		}
+		return true
+	}
+	if _, ok := m.data[key]; ok {
+		switch e.policy {
+		case EvictionPolicyLRU:
+			e.touchLRU(key)
+		case EvictionPolicyLFU:
+			e.touchLFU(key)
+		}
+	}
+	m.mu.Unlock()
+	return false
+}
+
+// TTL returns the remaining time-to-live of <key>. It returns zero if the key
+// does not exist or carries no TTL.
+func (m *IntStrMap) TTL(key int) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.evict == nil {
+		return 0
+	}
+	if _, ok := m.data[key]; !ok {
+		return 0
+	}
+	return m.evict.ttlOf(key)
+}
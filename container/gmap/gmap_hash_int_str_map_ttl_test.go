@@ -0,0 +1,78 @@
+// Copyright 2017 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestIntStrMap_SetWithTTL_Expires(t *testing.T) {
+	m := NewIntStrMap(true)
+	m.SetWithTTL(1, "a", 20*time.Millisecond)
+	if v, ok := m.Search(1); !ok || v != "a" {
+		t.Fatalf("expected key 1 to be present with value %q before expiry, got %q (found=%v)", "a", v, ok)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if _, ok := m.Search(1); ok {
+		t.Fatalf("expected key 1 to have expired and been swept")
+	}
+}
+
+func TestIntStrMap_Sweeper_DoesNotLeakGoroutines(t *testing.T) {
+	m := NewIntStrMap(true)
+
+	before := runtime.NumGoroutine()
+
+	// Cycle the heap empty -> non-empty several times: each transition used
+	// to spawn a brand new sweeper goroutine that never exited.
+	for i := 0; i < 5; i++ {
+		m.SetWithTTL(i, "v", 10*time.Millisecond)
+		time.Sleep(60 * time.Millisecond)
+		if _, ok := m.Search(i); ok {
+			t.Fatalf("expected key %d to have expired before the next cycle", i)
+		}
+	}
+
+	// Give the last sweeper time to observe the drained heap and exit.
+	var after int
+	deadline := time.Now().Add(time.Second)
+	for {
+		runtime.Gosched()
+		after = runtime.NumGoroutine()
+		if after <= before+1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after > before+1 {
+		t.Fatalf("goroutine count grew from %d to %d after repeated TTL cycles; sweeper is leaking goroutines", before, after)
+	}
+}
+
+func TestIntStrMap_EvictionLRU(t *testing.T) {
+	m := NewIntStrMapWithEviction(EvictionMaxSize(2), EvictionWithPolicy(EvictionPolicyLRU))
+	m.Set(1, "a")
+	m.Set(2, "b")
+	// Touch key 1 so key 2 becomes the least-recently-used entry.
+	m.Get(1)
+	m.Set(3, "c")
+
+	if _, ok := m.Search(2); ok {
+		t.Fatalf("expected key 2 to have been evicted as least-recently-used")
+	}
+	if v, ok := m.Search(1); !ok || v != "a" {
+		t.Fatalf("expected key 1 to survive eviction, got %q (found=%v)", v, ok)
+	}
+	if v, ok := m.Search(3); !ok || v != "c" {
+		t.Fatalf("expected key 3 to survive eviction, got %q (found=%v)", v, ok)
+	}
+	if size := m.Size(); size != 2 {
+		t.Fatalf("expected map size to stay capped at 2, got %d", size)
+	}
+}
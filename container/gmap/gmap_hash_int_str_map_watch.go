@@ -0,0 +1,242 @@
+// Copyright 2017 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import "sync"
+
+// EventType describes the kind of mutation an Event reports.
+type EventType int
+
+const (
+	// EventTypeSet is fired when a key is created or its value overwritten.
+	EventTypeSet EventType = iota
+	// EventTypeRemove is fired when a key is deleted from the map.
+	EventTypeRemove
+	// EventTypeClear is fired once when the whole map is emptied by Clear.
+	EventTypeClear
+)
+
+// Event describes a single mutation of an IntStrMap.
+type Event struct {
+	Type     EventType
+	Key      int
+	OldValue string
+	NewValue string
+}
+
+// defaultWatchChanSize is the capacity of a single subscriber's event channel.
+// Once full, the subscriber's DropOldest policy discards its oldest pending
+// event to make room, so a slow subscriber can never block a writer.
+const defaultWatchChanSize = 64
+
+// watchSub is a single-event subscriber registered through Watch.
+type watchSub struct {
+	mu   sync.Mutex
+	ch   chan Event
+	done chan struct{}
+}
+
+// batchSub is a subscriber registered through BatchWatch, receiving the
+// events of one bulk operation as a single slice.
+type batchSub struct {
+	mu   sync.Mutex
+	ch   chan []Event
+	done chan struct{}
+}
+
+// intStrMapDispatcher fans mutation events of an IntStrMap out to its
+// subscribers. It owns its own mutex so that dispatching never needs the
+// map's own <mu>, keeping writers from blocking on slow subscribers.
+type intStrMapDispatcher struct {
+	mu        sync.Mutex
+	nextID    int
+	subs      map[int]*watchSub
+	batchSubs map[int]*batchSub
+}
+
+func newIntStrMapDispatcher() *intStrMapDispatcher {
+	return &intStrMapDispatcher{
+		subs:      make(map[int]*watchSub),
+		batchSubs: make(map[int]*batchSub),
+	}
+}
+
+// pushEvent enqueues <ev> onto <sub>, dropping the oldest queued event first
+// if the channel is already full.
+func pushEvent(sub *watchSub, ev Event) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	select {
+	case sub.ch <- ev:
+		return
+	default:
+	}
+	select {
+	case <-sub.ch:
+	default:
+	}
+	select {
+	case sub.ch <- ev:
+	default:
+	}
+}
+
+// pushBatch enqueues <evs> onto <sub>, dropping the oldest queued batch first
+// if the channel is already full.
+func pushBatch(sub *batchSub, evs []Event) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	select {
+	case sub.ch <- evs:
+		return
+	default:
+	}
+	select {
+	case <-sub.ch:
+	default:
+	}
+	select {
+	case sub.ch <- evs:
+	default:
+	}
+}
+
+// dispatch delivers <evs> to every subscriber: single-event subscribers
+// receive one call per event, in order, while batch subscribers receive the
+// whole slice as a single call.
+func (d *intStrMapDispatcher) dispatch(evs []Event) {
+	if len(evs) == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ev := range evs {
+		for _, sub := range d.subs {
+			pushEvent(sub, ev)
+		}
+	}
+	for _, sub := range d.batchSubs {
+		pushBatch(sub, evs)
+	}
+}
+
+// ensureWatch lazily creates the map's event dispatcher.
+func (m *IntStrMap) ensureWatch() *intStrMapDispatcher {
+	m.mu.Lock()
+	if m.watch == nil {
+		m.watch = newIntStrMapDispatcher()
+	}
+	d := m.watch
+	m.mu.Unlock()
+	return d
+}
+
+// Watch registers <f> to be called, on its own goroutine, for every
+// subsequent mutation of the map. It returns an unsubscribe function that
+// stops further delivery; it is safe to call more than once.
+func (m *IntStrMap) Watch(f func(event Event)) (unsubscribe func()) {
+	d := m.ensureWatch()
+	sub := &watchSub{
+		ch:   make(chan Event, defaultWatchChanSize),
+		done: make(chan struct{}),
+	}
+	d.mu.Lock()
+	id := d.nextID
+	d.nextID++
+	d.subs[id] = sub
+	d.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case ev := <-sub.ch:
+				f(ev)
+			case <-sub.done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			delete(d.subs, id)
+			d.mu.Unlock()
+			close(sub.done)
+		})
+	}
+}
+
+// BatchWatch registers <f> to be called, on its own goroutine, once per bulk
+// operation (Sets, Removes, Pops, Merge, ...) with all of that operation's
+// events, instead of once per event. It returns an unsubscribe function that
+// stops further delivery; it is safe to call more than once.
+func (m *IntStrMap) BatchWatch(f func(events []Event)) (unsubscribe func()) {
+	d := m.ensureWatch()
+	sub := &batchSub{
+		ch:   make(chan []Event, defaultWatchChanSize),
+		done: make(chan struct{}),
+	}
+	d.mu.Lock()
+	id := d.nextID
+	d.nextID++
+	d.batchSubs[id] = sub
+	d.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case evs := <-sub.ch:
+				f(evs)
+			case <-sub.done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			delete(d.batchSubs, id)
+			d.mu.Unlock()
+			close(sub.done)
+		})
+	}
+}
+
+// diffIntStrMap compares <before> and <after>, turning every added, changed
+// or removed key into an Event. It is used by LockFunc, where the caller
+// mutates the map directly and the dispatcher has no other way to learn
+// which keys changed.
+func diffIntStrMap(before, after map[int]string) []Event {
+	var events []Event
+	for k, v := range after {
+		if old, ok := before[k]; !ok || old != v {
+			events = append(events, Event{Type: EventTypeSet, Key: k, OldValue: old, NewValue: v})
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok {
+			events = append(events, Event{Type: EventTypeRemove, Key: k, OldValue: v})
+		}
+	}
+	return events
+}
+
+// notify fans <evs> out to subscribers if the map has any; it is a no-op,
+// at the cost of a single nil check, for maps that nobody is watching.
+func (m *IntStrMap) notify(evs ...Event) {
+	m.mu.RLock()
+	d := m.watch
+	m.mu.RUnlock()
+	if d == nil {
+		return
+	}
+	d.dispatch(evs)
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntStrMap_Watch_DropOldest(t *testing.T) {
+	m := NewIntStrMap(true)
+	sub := &watchSub{
+		ch:   make(chan Event, 2),
+		done: make(chan struct{}),
+	}
+
+	// Fill the channel past capacity directly, bypassing the subscriber's
+	// goroutine so none of these sends are ever drained concurrently.
+	pushEvent(sub, Event{Type: EventTypeSet, Key: 1, NewValue: "a"})
+	pushEvent(sub, Event{Type: EventTypeSet, Key: 2, NewValue: "b"})
+	pushEvent(sub, Event{Type: EventTypeSet, Key: 3, NewValue: "c"})
+
+	if got := len(sub.ch); got != 2 {
+		t.Fatalf("expected channel to stay capped at its capacity of 2, got %d queued events", got)
+	}
+
+	first := <-sub.ch
+	second := <-sub.ch
+	if first.Key != 2 || second.Key != 3 {
+		t.Fatalf("expected oldest event (key 1) to have been dropped, got keys %d then %d", first.Key, second.Key)
+	}
+
+	_ = m
+}
+
+func TestIntStrMap_Watch_SlowSubscriberDoesNotBlockWriter(t *testing.T) {
+	m := NewIntStrMap(true)
+
+	// Register a subscriber whose callback never returns, so its goroutine
+	// never drains the channel; the writer must still be able to proceed.
+	block := make(chan struct{})
+	unsubscribe := m.Watch(func(event Event) {
+		<-block
+	})
+	defer func() {
+		close(block)
+		unsubscribe()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < defaultWatchChanSize*2; i++ {
+			m.Set(i, "v")
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Set calls blocked on a slow Watch subscriber instead of dropping oldest events")
+	}
+}
+
+func TestIntStrMap_BatchWatch_ReceivesBulkEvents(t *testing.T) {
+	m := NewIntStrMap(true)
+
+	eventsCh := make(chan []Event, 1)
+	unsubscribe := m.BatchWatch(func(events []Event) {
+		eventsCh <- events
+	})
+	defer unsubscribe()
+
+	m.Sets(map[int]string{1: "a", 2: "b"})
+
+	select {
+	case evs := <-eventsCh:
+		if len(evs) != 2 {
+			t.Fatalf("expected one batch of 2 events from Sets, got %d", len(evs))
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for batch watch callback")
+	}
+}